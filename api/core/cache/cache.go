@@ -0,0 +1,30 @@
+package cache
+
+import "time"
+
+// Entry is a single cached response: the raw JSON body plus the validators
+// needed to revalidate it with the origin once its TTL has passed.
+type Entry struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	StoredAt     time.Time
+	TTL          time.Duration
+}
+
+// Expired reports whether e is past its TTL and should be revalidated with
+// the origin (via If-None-Match/If-Modified-Since) before being served
+// again.
+func (e *Entry) Expired() bool {
+	return e.TTL > 0 && time.Since(e.StoredAt) > e.TTL
+}
+
+// ResponseCache is consulted by handlers before issuing a GET request
+// marked cacheable via request.SetCacheable, and updated after every such
+// response. Implementations must be safe for concurrent use; adapters for
+// Redis-style backends just need to satisfy this interface.
+type ResponseCache interface {
+	Get(key string) (*Entry, bool)
+	Set(key string, entry *Entry)
+	Delete(key string)
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+)
+
+// shardCount is fixed rather than configurable: it only needs to be large
+// enough to spread lock contention across goroutines, not tuned per
+// deployment.
+const shardCount = 32
+
+// LRU is a bounded-size ResponseCache split into independently-locked
+// shards, so concurrent Get/Set calls on different keys don't contend on a
+// single mutex.
+type LRU struct {
+	shards [shardCount]*lruShard
+}
+
+// NewLRU builds an LRU holding at most maxEntriesPerShard entries in each
+// of its shards (so roughly shardCount*maxEntriesPerShard entries total).
+func NewLRU(maxEntriesPerShard int) *LRU {
+	if maxEntriesPerShard <= 0 {
+		maxEntriesPerShard = 256
+	}
+	l := &LRU{}
+	for i := range l.shards {
+		l.shards[i] = newLRUShard(maxEntriesPerShard)
+	}
+	return l
+}
+
+func (l *LRU) shardFor(key string) *lruShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return l.shards[h.Sum32()%shardCount]
+}
+
+// Get implements ResponseCache.
+func (l *LRU) Get(key string) (*Entry, bool) { return l.shardFor(key).get(key) }
+
+// Set implements ResponseCache.
+func (l *LRU) Set(key string, entry *Entry) { l.shardFor(key).set(key, entry) }
+
+// Delete implements ResponseCache.
+func (l *LRU) Delete(key string) { l.shardFor(key).delete(key) }
+
+type lruShard struct {
+	mu     sync.Mutex
+	maxLen int
+	ll     *list.List
+	items  map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry *Entry
+}
+
+func newLRUShard(maxLen int) *lruShard {
+	return &lruShard{
+		maxLen: maxLen,
+		ll:     list.New(),
+		items:  make(map[string]*list.Element),
+	}
+}
+
+func (s *lruShard) get(key string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	s.ll.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+func (s *lruShard) set(key string, entry *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		el.Value.(*lruItem).entry = entry
+		s.ll.MoveToFront(el)
+		return
+	}
+	s.items[key] = s.ll.PushFront(&lruItem{key: key, entry: entry})
+	if s.ll.Len() > s.maxLen {
+		oldest := s.ll.Back()
+		if oldest != nil {
+			s.ll.Remove(oldest)
+			delete(s.items, oldest.Value.(*lruItem).key)
+		}
+	}
+}
+
+func (s *lruShard) delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		s.ll.Remove(el)
+		delete(s.items, key)
+	}
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLRUGetSetDelete(t *testing.T) {
+	l := NewLRU(4)
+	entry := &Entry{Body: []byte("hello")}
+	if _, ok := l.Get("missing"); ok {
+		t.Fatalf("expected miss for unset key")
+	}
+	l.Set("k", entry)
+	got, ok := l.Get("k")
+	if !ok || string(got.Body) != "hello" {
+		t.Fatalf("expected hit with body %q, got %+v, ok=%v", "hello", got, ok)
+	}
+	l.Delete("k")
+	if _, ok := l.Get("k"); ok {
+		t.Fatalf("expected miss after delete")
+	}
+}
+
+func TestLRUEvictsOldestPerShard(t *testing.T) {
+	s := newLRUShard(2)
+	s.set("a", &Entry{})
+	s.set("b", &Entry{})
+	s.set("c", &Entry{}) // evicts "a", the least recently used
+	if _, ok := s.get("a"); ok {
+		t.Fatalf("expected \"a\" to be evicted")
+	}
+	if _, ok := s.get("b"); !ok {
+		t.Fatalf("expected \"b\" to survive eviction")
+	}
+	if _, ok := s.get("c"); !ok {
+		t.Fatalf("expected \"c\" to survive eviction")
+	}
+}
+
+func TestEntryExpired(t *testing.T) {
+	fresh := &Entry{StoredAt: time.Now(), TTL: time.Minute}
+	if fresh.Expired() {
+		t.Fatalf("expected fresh entry to not be expired")
+	}
+	stale := &Entry{StoredAt: time.Now().Add(-time.Hour), TTL: time.Minute}
+	if !stale.Expired() {
+		t.Fatalf("expected stale entry to be expired")
+	}
+	noTTL := &Entry{StoredAt: time.Now().Add(-24 * time.Hour)}
+	if noTTL.Expired() {
+		t.Fatalf("expected zero TTL to mean no expiry")
+	}
+}
+
+func TestLRUShardsSpreadKeys(t *testing.T) {
+	l := NewLRU(256)
+	seen := map[int]bool{}
+	for i := 0; i < 256; i++ {
+		shard := l.shardFor(fmt.Sprintf("key-%d", i))
+		for idx, sh := range l.shards {
+			if sh == shard {
+				seen[idx] = true
+			}
+		}
+	}
+	if len(seen) < 2 {
+		t.Fatalf("expected keys to spread across shards, only used %d", len(seen))
+	}
+}
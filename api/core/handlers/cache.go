@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/larksuite/oapi-sdk-go/api/core/cache"
+	"github.com/larksuite/oapi-sdk-go/api/core/request"
+	"github.com/larksuite/oapi-sdk-go/core"
+	"github.com/larksuite/oapi-sdk-go/core/config"
+	coreconst "github.com/larksuite/oapi-sdk-go/core/constants"
+)
+
+// Context keys the cache lookup stashes for later stages of the same
+// attempt: the key it looked up (so a 304 can refresh the same entry) and
+// the stale entry itself (so addConditionalHeaders can validate against
+// it).
+const (
+	ctxKeyCacheKey   = "lark.cache.key"
+	ctxKeyCacheEntry = "lark.cache.entry"
+)
+
+// cacheKey scopes a cache entry to method + full URL + access-token-type +
+// tenant/user, hashing the user token rather than storing it verbatim, so a
+// cache hit can never leak across tenants or users.
+func cacheKey(conf *config.Config, req *request.Request) string {
+	scope := req.TenantKey
+	if req.AccessTokenType == request.AccessTokenTypeUser {
+		sum := sha256.Sum256([]byte(req.UserAccessToken))
+		scope = hex.EncodeToString(sum[:])
+	}
+	return strings.Join([]string{req.HttpMethod, req.FullUrl(conf.GetDomain()), string(req.AccessTokenType), scope}, "|")
+}
+
+// serveFromCache answers req directly from the cache when it's a cacheable
+// GET with a fresh entry, bypassing build/sign/send entirely. A stale entry
+// is left on ctx for addConditionalHeaders and reuseCachedOn304 to use.
+func serveFromCache(ctx *core.Context, req *request.Request) bool {
+	return serveFromCacheWithConfig(ctx, config.ByCtx(ctx), req)
+}
+
+// serveFromCacheWithConfig is serveFromCache's decision logic with conf
+// taken explicitly, the same way cacheKey does, so tests can exercise it
+// with a *config.Config built directly instead of one threaded through ctx.
+func serveFromCacheWithConfig(ctx *core.Context, conf *config.Config, req *request.Request) bool {
+	if req.HttpMethod != http.MethodGet || !req.Cacheable {
+		return false
+	}
+	rc := conf.GetResponseCache()
+	if rc == nil {
+		return false
+	}
+	key := cacheKey(conf, req)
+	ctx.Set(ctxKeyCacheKey, key)
+	instruments := newRequestInstruments(conf)
+	entry, ok := rc.Get(key)
+	if !ok {
+		instruments.cacheMissCount.Add(ctx, 1)
+		return false
+	}
+	ctx.Set(ctxKeyCacheEntry, entry)
+	if entry.Expired() {
+		return false // stale: fall through to build a conditional request
+	}
+	if !req.DataFilled() {
+		instruments.cacheHitCount.Add(ctx, 1)
+		req.Err = fmt.Errorf("request out do not write")
+		return true
+	}
+	if err := unmarshalJSON(req.Output, req.IsNotDataField, bytes.NewReader(entry.Body)); err != nil {
+		// The cached bytes are bad (e.g. a Redis-backed ResponseCache
+		// returning stale-schema data), not a backend failure: purge the
+		// entry and fall through to a real request instead of handing
+		// req.Err back with req.HTTPResponse left nil, which Handle's
+		// RetryPolicy would otherwise treat exactly like a transport
+		// failure — retrying the same unparseable entry for up to
+		// MaxElapsedTime since nothing would ever evict it.
+		rc.Delete(key)
+		return false
+	}
+	instruments.cacheHitCount.Add(ctx, 1)
+	return true
+}
+
+// addConditionalHeaders attaches If-None-Match/If-Modified-Since to a
+// request carrying a stale cache entry, so the origin can answer 304.
+func addConditionalHeaders(ctx *core.Context, req *request.Request) {
+	if req.HTTPRequest == nil {
+		return
+	}
+	v, ok := ctx.Get(ctxKeyCacheEntry)
+	if !ok {
+		return
+	}
+	entry := v.(*cache.Entry)
+	if entry.ETag != "" {
+		req.HTTPRequest.Header.Set("If-None-Match", entry.ETag)
+	}
+	if entry.LastModified != "" {
+		req.HTTPRequest.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+}
+
+// reuseCachedOn304 treats a 304 response as a cache hit: it refreshes the
+// entry's TTL window in the cache and rewrites resp so validateResponse and
+// unmarshalResponse see the cached JSON body as if the origin had sent it.
+// It reports whether it handled a 304 this way.
+func reuseCachedOn304(ctx *core.Context, req *request.Request) bool {
+	if req.HTTPResponse == nil || req.HTTPResponse.StatusCode != http.StatusNotModified {
+		return false
+	}
+	v, ok := ctx.Get(ctxKeyCacheEntry)
+	if !ok {
+		return false
+	}
+	stale := v.(*cache.Entry)
+	conf := config.ByCtx(ctx)
+	newRequestInstruments(conf).cacheHitCount.Add(ctx, 1)
+	// stale is the *cache.Entry the LRU handed out to whichever callers are
+	// reading it concurrently (Get never copies); refreshing it in place
+	// would race with a concurrent Expired() check or 304. Build a fresh
+	// entry with the refreshed StoredAt and Set that instead.
+	entry := &cache.Entry{
+		Body:         stale.Body,
+		ETag:         stale.ETag,
+		LastModified: stale.LastModified,
+		StoredAt:     time.Now(),
+		TTL:          stale.TTL,
+	}
+	if rc := conf.GetResponseCache(); rc != nil {
+		if key, ok := ctx.Get(ctxKeyCacheKey); ok {
+			rc.Set(key.(string), entry)
+		}
+	}
+	resp := req.HTTPResponse
+	resp.StatusCode = http.StatusOK
+	resp.Header.Set(coreconst.ContentType, coreconst.ContentTypeJson)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(entry.Body))
+	return true
+}
+
+// captureResponseForCache buffers a cacheable GET's 200 response body so it
+// can be replayed on a later cache hit, without disturbing the stream
+// validateResponse/unmarshalResponse read from normally.
+func captureResponseForCache(ctx *core.Context, req *request.Request) {
+	if req.HttpMethod != http.MethodGet || !req.Cacheable || req.HTTPResponse == nil {
+		return
+	}
+	if req.HTTPResponse.StatusCode != http.StatusOK {
+		return
+	}
+	conf := config.ByCtx(ctx)
+	rc := conf.GetResponseCache()
+	if rc == nil {
+		return
+	}
+	resp := req.HTTPResponse
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		req.Err = err
+		return
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	rc.Set(cacheKey(conf, req), &cache.Entry{
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		StoredAt:     time.Now(),
+		TTL:          req.CacheTTL,
+	})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/larksuite/oapi-sdk-go/api/core/cache"
+	"github.com/larksuite/oapi-sdk-go/api/core/request"
+	"github.com/larksuite/oapi-sdk-go/core"
+	"github.com/larksuite/oapi-sdk-go/core/config"
+)
+
+func TestCacheKeyScopesByTenant(t *testing.T) {
+	conf := &config.Config{}
+	reqA := &request.Request{HttpMethod: http.MethodGet, AccessTokenType: request.AccessTokenTypeTenant, TenantKey: "tenant-a"}
+	reqB := &request.Request{HttpMethod: http.MethodGet, AccessTokenType: request.AccessTokenTypeTenant, TenantKey: "tenant-b"}
+
+	keyA := cacheKey(conf, reqA)
+	keyB := cacheKey(conf, reqB)
+	if keyA == keyB {
+		t.Fatalf("expected different tenants to get different cache keys, both got %q", keyA)
+	}
+}
+
+func TestCacheKeyScopesByUserToken(t *testing.T) {
+	conf := &config.Config{}
+	reqA := &request.Request{HttpMethod: http.MethodGet, AccessTokenType: request.AccessTokenTypeUser, UserAccessToken: "user-a-token"}
+	reqB := &request.Request{HttpMethod: http.MethodGet, AccessTokenType: request.AccessTokenTypeUser, UserAccessToken: "user-b-token"}
+
+	keyA := cacheKey(conf, reqA)
+	keyB := cacheKey(conf, reqB)
+	if keyA == keyB {
+		t.Fatalf("expected different user access tokens to get different cache keys, both got %q", keyA)
+	}
+	if containsToken(keyA, "user-a-token") {
+		t.Fatalf("expected the raw user access token to be hashed out of the cache key, got %q", keyA)
+	}
+}
+
+func TestCacheKeyIsStableForIdenticalRequests(t *testing.T) {
+	conf := &config.Config{}
+	req := func() *request.Request {
+		return &request.Request{HttpMethod: http.MethodGet, AccessTokenType: request.AccessTokenTypeTenant, TenantKey: "tenant-a"}
+	}
+	if cacheKey(conf, req()) != cacheKey(conf, req()) {
+		t.Fatalf("expected identical requests to produce the same cache key")
+	}
+}
+
+func TestCacheKeyDoesNotCrossAccessTokenTypes(t *testing.T) {
+	conf := &config.Config{}
+	tenantReq := &request.Request{HttpMethod: http.MethodGet, AccessTokenType: request.AccessTokenTypeTenant, TenantKey: "same"}
+	appReq := &request.Request{HttpMethod: http.MethodGet, AccessTokenType: request.AccessTokenTypeApp}
+
+	if cacheKey(conf, tenantReq) == cacheKey(conf, appReq) {
+		t.Fatalf("expected different access token types to never collide on the same cache key")
+	}
+}
+
+func TestServeFromCachePurgesEntryOnUnmarshalFailure(t *testing.T) {
+	conf := &config.Config{}
+	rc := cache.NewLRU(8)
+	conf.SetResponseCache(rc)
+
+	req := &request.Request{HttpMethod: http.MethodGet, Cacheable: true, Output: &map[string]interface{}{}}
+	key := cacheKey(conf, req)
+	rc.Set(key, &cache.Entry{Body: []byte("not valid json")})
+
+	ctx := core.WarpContext(context.Background())
+	if serveFromCacheWithConfig(ctx, conf, req) {
+		t.Fatalf("expected serveFromCache to fall through to a real request on a corrupt cache entry")
+	}
+	if req.Err != nil {
+		t.Fatalf("expected req.Err to stay nil so the retry policy and circuit breaker never see this as a transport failure, got %v", req.Err)
+	}
+	if _, ok := rc.Get(key); ok {
+		t.Fatalf("expected the corrupt cache entry to be purged, but it's still present")
+	}
+}
+
+func containsToken(key, token string) bool {
+	for i := 0; i+len(token) <= len(key); i++ {
+		if key[i:i+len(token)] == token {
+			return true
+		}
+	}
+	return false
+}
@@ -9,20 +9,28 @@ import (
 	"github.com/larksuite/oapi-sdk-go/api/core/request"
 	"github.com/larksuite/oapi-sdk-go/api/core/response"
 	"github.com/larksuite/oapi-sdk-go/api/core/token"
-	"github.com/larksuite/oapi-sdk-go/api/core/transport"
 	"github.com/larksuite/oapi-sdk-go/core"
 	"github.com/larksuite/oapi-sdk-go/core/config"
 	coreconst "github.com/larksuite/oapi-sdk-go/core/constants"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
-	"os"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 )
 
-const defaultMaxRetryCount = 1
+// defaultMaxTokenRefreshRetries bounds the token-refresh retry path
+// (req.Retryable true but the RetryPolicy itself wouldn't otherwise retry)
+// independently of RetryPolicy.MaxElapsedTime, so a backend that keeps
+// rejecting a refreshed token — e.g. a misconfigured app secret — can't
+// turn into an unbounded retry loop.
+const defaultMaxTokenRefreshRetries = 1
 
 var defaultHTTPRequestHeader = map[string]string{}
 var Default = &Handlers{}
@@ -41,6 +49,17 @@ func init() {
 
 type Handler func(*core.Context, *request.Request)
 
+// Stage identifies one of the steps in the request lifecycle that Handlers
+// runs through on every call. It is the unit middleware hooks attach to.
+type Stage string
+
+const (
+	StageBuild             Stage = "build"
+	StageSign              Stage = "sign"
+	StageValidateResponse  Stage = "validateResponse"
+	StageUnmarshalResponse Stage = "unmarshalResponse"
+)
+
 type Handlers struct {
 	init              Handler
 	validate          Handler
@@ -50,6 +69,92 @@ type Handlers struct {
 	unmarshalResponse Handler
 	retry             Handler // when token invalid, retry
 	complement        Handler
+
+	mu      sync.RWMutex
+	before  map[Stage][]Handler
+	after   map[Stage][]Handler
+	finally map[Stage][]Handler
+}
+
+// Use registers h to run immediately before stage, in registration order.
+// It lets callers add tracing, metrics, request/response mutation, or
+// custom auth to a stage without forking the SDK. If h sets req.Err, the
+// stage itself and any remaining before/after hooks are skipped; hooks
+// registered with UseFinally still run.
+func (hs *Handlers) Use(stage Stage, h Handler) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hs.before == nil {
+		hs.before = make(map[Stage][]Handler)
+	}
+	hs.before[stage] = append(hs.before[stage], h)
+}
+
+// UseAfter registers h to run immediately after stage completes
+// successfully, in registration order.
+func (hs *Handlers) UseAfter(stage Stage, h Handler) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hs.after == nil {
+		hs.after = make(map[Stage][]Handler)
+	}
+	hs.after[stage] = append(hs.after[stage], h)
+}
+
+// UseFinally registers h to run after stage unconditionally, even when the
+// stage or one of its before/after hooks set req.Err — useful for cleanup
+// such as closing a span or recording a metric that must observe every
+// attempt, not just the successful ones.
+func (hs *Handlers) UseFinally(stage Stage, h Handler) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hs.finally == nil {
+		hs.finally = make(map[Stage][]Handler)
+	}
+	hs.finally[stage] = append(hs.finally[stage], h)
+}
+
+// SetBuild overrides the Handler that runs for StageBuild.
+func (hs *Handlers) SetBuild(h Handler) { hs.build = h }
+
+// SetSign overrides the Handler that runs for StageSign.
+func (hs *Handlers) SetSign(h Handler) { hs.sign = h }
+
+// SetValidateResponse overrides the Handler that runs for StageValidateResponse.
+func (hs *Handlers) SetValidateResponse(h Handler) { hs.validateResponse = h }
+
+// SetUnmarshalResponse overrides the Handler that runs for StageUnmarshalResponse.
+func (hs *Handlers) SetUnmarshalResponse(h Handler) { hs.unmarshalResponse = h }
+
+// runStage runs the before hooks, h itself, then the after hooks for stage,
+// short-circuiting as soon as any of them sets req.Err.
+func (hs *Handlers) runStage(ctx *core.Context, req *request.Request, stage Stage, h Handler) {
+	hs.mu.RLock()
+	before := hs.before[stage]
+	after := hs.after[stage]
+	finally := hs.finally[stage]
+	hs.mu.RUnlock()
+	defer func() {
+		for _, hook := range finally {
+			hook(ctx, req)
+		}
+	}()
+	for _, hook := range before {
+		hook(ctx, req)
+		if req.Err != nil {
+			return
+		}
+	}
+	h(ctx, req)
+	if req.Err != nil {
+		return
+	}
+	for _, hook := range after {
+		hook(ctx, req)
+		if req.Err != nil {
+			return
+		}
+	}
 }
 
 func Handle(ctx *core.Context, req *request.Request) {
@@ -62,28 +167,154 @@ func Handle(ctx *core.Context, req *request.Request) {
 	if req.Err != nil {
 		return
 	}
-	i := 0
+	conf := config.ByCtx(ctx)
+	if timeout := conf.GetRequestTimeout(); timeout > 0 {
+		if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+			cancel := ctx.SetTimeout(timeout)
+			defer cancel()
+		}
+	}
+
+	fullURL := req.FullUrl(conf.GetDomain())
+	_, rootSpan := tracer(ctx).Start(ctx, "lark.request", trace.WithAttributes(requestSpanAttributes(conf, req, fullURL)...))
+	ctx.Set(ctxKeyRootSpan, rootSpan)
+	instruments := newRequestInstruments(conf)
+	start := time.Now()
+	defer func() {
+		if req.Err != nil {
+			rootSpan.RecordError(req.Err)
+		} else if requestID := ctx.GetRequestID(); requestID != "" {
+			rootSpan.SetAttributes(attribute.String("request_id", requestID))
+		}
+		rootSpan.End()
+		instruments.requestCount.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("route", routeOf(fullURL)),
+			attribute.Int("http.status_code", ctx.GetHTTPStatusCode()),
+		))
+		instruments.latencyMs.Record(ctx, float64(time.Since(start).Milliseconds()))
+	}()
+
+	// A cacheable GET answered straight from the response cache never
+	// touches the network, so it must bypass the circuit breaker and retry
+	// policy entirely: gating it on breaker.Allow would reject a request
+	// that's sitting in cache during a backend outage — exactly backwards,
+	// since serving from cache during an outage is the point of caching —
+	// and feeding the outcome to breaker.RecordResult or policy.NextBackoff
+	// would judge the backend's health from an attempt that never reached
+	// it.
+	if serveFromCache(ctx, req) {
+		return
+	}
+
+	breaker := conf.GetCircuitBreaker()
+	route := routeOf(fullURL)
+
+	policy := conf.GetRetryPolicy()
+	attempt := 0
+	tokenRefreshAttempt := 0
 	for {
-		i++
+		// Allow is consulted on every attempt, not just before the loop: a
+		// 5xx on the first attempt can trip the breaker to Open immediately,
+		// and the retries that follow must stop issuing HTTP calls rather
+		// than ride out the rest of this Handle invocation.
+		if !breaker.Allow(route) {
+			instruments.circuitOpenCount.Add(ctx, 1, metric.WithAttributes(attribute.String("route", route)))
+			req.Err = errors.ErrCircuitOpen
+			return
+		}
 		Default.send(ctx, req)
-		if !req.Retryable || i > defaultMaxRetryCount {
+		breaker.RecordResult(route, !isCircuitFailure(req))
+		// policy.NextBackoff judges retryability from the HTTP status/
+		// transport error directly, so it's consulted unconditionally —
+		// req.Retryable only layers the pre-existing token-refresh signal
+		// on top (an immediate retry, no backoff) for cases the policy
+		// itself wouldn't otherwise retry.
+		delay, policyRetry := policy.NextBackoff(attempt, start, req, req.HTTPResponse, req.Err)
+		if !policyRetry {
+			if !req.Retryable || tokenRefreshAttempt >= defaultMaxTokenRefreshRetries {
+				return
+			}
+			delay = 0
+			tokenRefreshAttempt++
+		}
+		instruments.retryCount.Add(ctx, 1)
+		_, retrySpan := tracer(ctx).Start(trace.ContextWithSpan(ctx, rootSpan), "retry",
+			trace.WithAttributes(attribute.Int("attempt", attempt), attribute.Int64("delay_ms", delay.Milliseconds())))
+		retrySpan.End()
+		conf.GetLogger().Debug(ctx, fmt.Sprintf("[retry] attempt:%d, request:%v, err: %v, delay:%v", attempt, req, req.Err, delay))
+		if !waitOrDone(ctx, delay) {
+			return
+		}
+		if err := req.ResetBody(); err != nil {
+			req.Err = err
 			return
 		}
-		config.ByCtx(ctx).GetLogger().Debug(ctx, fmt.Sprintf("[retry] request:%v, err: %v", req, req.Err))
+		attempt++
 		req.Err = nil
+		req.HTTPResponse = nil
 	}
 }
 
+// waitOrDone blocks for d, returning false early if ctx is already done. A
+// non-positive d still checks ctx.Done() rather than returning true
+// unconditionally, so a chain of zero-delay retries — the token-refresh
+// path in Handle — can be cancelled instead of spinning uncancellably against
+// the backend.
+func waitOrDone(ctx *core.Context, d time.Duration) bool {
+	if d <= 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		default:
+			return true
+		}
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isCircuitFailure reports whether req's outcome should count against the
+// circuit breaker: only 5xx responses and network errors (no response at
+// all) do. A 4xx error — including the *response.Error unmarshalJSON
+// produces for any non-OK business error code — is the caller's fault, not
+// the backend's, and must not trip the breaker for every other caller of
+// the same route.
+func isCircuitFailure(req *request.Request) bool {
+	if req.HTTPResponse != nil {
+		return req.HTTPResponse.StatusCode >= http.StatusInternalServerError
+	}
+	return req.Err != nil
+}
+
 func (hs *Handlers) send(ctx *core.Context, req *request.Request) {
-	hs.build(ctx, req)
+	hs.runStage(ctx, req, StageBuild, hs.build)
 	if req.Err != nil {
 		return
 	}
-	hs.sign(ctx, req)
+	addConditionalHeaders(ctx, req)
+	hs.runStage(ctx, req, StageSign, hs.sign)
 	if req.Err != nil {
 		return
 	}
-	resp, err := transport.DefaultClient.Do(req.HTTPRequest)
+	var sendSpan trace.Span
+	if root, ok := ctx.Get(ctxKeyRootSpan); ok {
+		if rootSpan, ok := root.(trace.Span); ok {
+			_, sendSpan = tracer(ctx).Start(trace.ContextWithSpan(ctx, rootSpan), "send")
+		}
+	}
+	resp, err := config.ByCtx(ctx).GetTransport().Do(req.HTTPRequest)
+	if sendSpan != nil {
+		if err != nil {
+			sendSpan.RecordError(err)
+		}
+		sendSpan.End()
+	}
 	if err != nil {
 		req.Err = err
 		return
@@ -91,12 +322,15 @@ func (hs *Handlers) send(ctx *core.Context, req *request.Request) {
 	ctx.Set(coreconst.HTTPHeaderKeyRequestID, resp.Header.Get(coreconst.HTTPHeaderKeyRequestID))
 	ctx.Set(coreconst.HTTPKeyStatusCode, resp.StatusCode)
 	req.HTTPResponse = resp
+	if !reuseCachedOn304(ctx, req) {
+		captureResponseForCache(ctx, req)
+	}
 	defer hs.retry(ctx, req)
-	hs.validateResponse(ctx, req)
+	hs.runStage(ctx, req, StageValidateResponse, hs.validateResponse)
 	if req.Err != nil {
 		return
 	}
-	hs.unmarshalResponse(ctx, req)
+	hs.runStage(ctx, req, StageUnmarshalResponse, hs.unmarshalResponse)
 }
 
 func initFunc(_ *core.Context, req *request.Request) {
@@ -130,6 +364,9 @@ func buildFunc(ctx *core.Context, req *request.Request) {
 			case *request.FormData:
 				reqBodyFromFormData(ctx, req)
 				conf.GetLogger().Debug(ctx, fmt.Sprintf("[build]request:\n%v\nbody:formdata", req))
+			case *io.SectionReader:
+				reqBodyFromSectionReader(ctx, req)
+				conf.GetLogger().Debug(ctx, fmt.Sprintf("[build]request:\n%v\nbody:chunked upload", req))
 			default:
 				reqBodyFromInput(ctx, req)
 				conf.GetLogger().Debug(ctx, fmt.Sprintf("[build]request:\n%v\nbody:%s", req, string(req.RequestBody)))
@@ -142,7 +379,7 @@ func buildFunc(ctx *core.Context, req *request.Request) {
 	if req.RequestBody != nil {
 		req.RequestBodyStream = bytes.NewBuffer(req.RequestBody)
 	}
-	if err := requestBodyStream(req); err != nil {
+	if err := req.ResetBody(); err != nil {
 		req.Err = err
 		return
 	}
@@ -155,24 +392,10 @@ func buildFunc(ctx *core.Context, req *request.Request) {
 		r.Header.Set(k, v)
 	}
 	r.Header.Set(coreconst.ContentType, req.ContentType)
-	req.HTTPRequest = r
-}
-
-func requestBodyStream(req *request.Request) error {
-	var err error
-	if seek, ok := req.RequestBodyStream.(io.Seeker); ok {
-		_, err = seek.Seek(0, 0)
-		if err != nil {
-			if pathError, ok := err.(*os.PathError); ok {
-				if pathError.Err == os.ErrClosed {
-					if file, ok := seek.(*os.File); ok {
-						req.RequestBodyStream, err = os.Open(file.Name())
-					}
-				}
-			}
-		}
+	if req.ContentRange != "" {
+		r.Header.Set("Content-Range", req.ContentRange)
 	}
-	return err
+	req.HTTPRequest = r
 }
 
 func signFunc(ctx *core.Context, req *request.Request) {
@@ -292,7 +515,9 @@ func applyAppTicket(ctx *core.Context) {
 	Handle(ctx, req)
 	if req.Err != nil {
 		conf.GetLogger().Error(ctx, req.Err)
+		return
 	}
+	newRequestInstruments(conf).tokenRefreshCount.Add(ctx, 1)
 }
 
 func unmarshalJSON(v interface{}, isNotDataField bool, stream io.Reader) error {
@@ -381,6 +606,17 @@ func reqBodyFromFormData(_ *core.Context, req *request.Request) {
 	}
 }
 
+// reqBodyFromSectionReader streams a resumable upload chunk's raw bytes as
+// the request body. Unlike reqBodyFromInput, req.Input here is the chunk's
+// file data rather than a value meant to be JSON-encoded, so it's set
+// directly as the stream ResetBody rewinds on retry instead of being run
+// through json.Encoder first.
+func reqBodyFromSectionReader(_ *core.Context, req *request.Request) {
+	section := req.Input.(*io.SectionReader)
+	req.ContentType = "application/octet-stream"
+	req.RequestBodyStream = section
+}
+
 func reqBodyFromInput(_ *core.Context, req *request.Request) {
 	var bs []byte
 	if input, ok := req.Input.(string); ok {
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/larksuite/oapi-sdk-go/api/core/request"
+	"github.com/larksuite/oapi-sdk-go/core"
+)
+
+func TestHandlersUseRunsHooksInRegistrationOrder(t *testing.T) {
+	hs := &Handlers{}
+	var order []string
+	hs.Use(StageBuild, func(_ *core.Context, _ *request.Request) { order = append(order, "before-1") })
+	hs.Use(StageBuild, func(_ *core.Context, _ *request.Request) { order = append(order, "before-2") })
+	hs.UseAfter(StageBuild, func(_ *core.Context, _ *request.Request) { order = append(order, "after") })
+	hs.UseFinally(StageBuild, func(_ *core.Context, _ *request.Request) { order = append(order, "finally") })
+
+	hs.runStage(core.WarpContext(context.Background()), &request.Request{}, StageBuild,
+		func(_ *core.Context, _ *request.Request) { order = append(order, "stage") })
+
+	want := []string{"before-1", "before-2", "stage", "after", "finally"}
+	if len(order) != len(want) {
+		t.Fatalf("expected order %v, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, order)
+		}
+	}
+}
+
+func TestHandlersUseShortCircuitsOnReqErr(t *testing.T) {
+	hs := &Handlers{}
+	var ran []string
+	hs.Use(StageBuild, func(_ *core.Context, req *request.Request) {
+		ran = append(ran, "before")
+		req.Err = errors.New("before failed")
+	})
+	hs.UseAfter(StageBuild, func(_ *core.Context, _ *request.Request) { ran = append(ran, "after") })
+	hs.UseFinally(StageBuild, func(_ *core.Context, _ *request.Request) { ran = append(ran, "finally") })
+
+	hs.runStage(core.WarpContext(context.Background()), &request.Request{}, StageBuild,
+		func(_ *core.Context, _ *request.Request) { ran = append(ran, "stage") })
+
+	for _, stage := range ran {
+		if stage == "stage" || stage == "after" {
+			t.Fatalf("expected the stage and after hooks to be skipped once a before hook sets req.Err, ran %v", ran)
+		}
+	}
+	if len(ran) != 2 || ran[0] != "before" || ran[1] != "finally" {
+		t.Fatalf("expected only before and finally hooks to run, got %v", ran)
+	}
+}
+
+func TestHandlersSetBuildOverridesStage(t *testing.T) {
+	hs := &Handlers{}
+	called := false
+	hs.SetBuild(func(_ *core.Context, _ *request.Request) { called = true })
+
+	hs.runStage(core.WarpContext(context.Background()), &request.Request{}, StageBuild, hs.build)
+
+	if !called {
+		t.Fatalf("expected the overridden build Handler to run")
+	}
+}
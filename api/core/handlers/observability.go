@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/url"
+
+	"github.com/larksuite/oapi-sdk-go/api/core/request"
+	"github.com/larksuite/oapi-sdk-go/core"
+	"github.com/larksuite/oapi-sdk-go/core/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies this package to OpenTelemetry's tracer and
+// meter registries.
+const instrumentationName = "github.com/larksuite/oapi-sdk-go/api/core/handlers"
+
+// Context keys the root span and per-stage spans are stashed under via
+// core.Context's generic Set/Get, the same mechanism used for request ID
+// and status code, so the Use/UseAfter hooks below can find them without
+// changing any Handler's signature.
+const (
+	ctxKeyRootSpan    = "lark.otel.root_span"
+	ctxKeyStagePrefix = "lark.otel.stage."
+)
+
+func init() {
+	for _, stage := range []Stage{StageBuild, StageSign, StageValidateResponse, StageUnmarshalResponse} {
+		stage := stage
+		Default.Use(stage, startStageSpan(stage))
+		Default.UseFinally(stage, endStageSpan(stage))
+	}
+	Default.UseAfter(StageBuild, injectTraceparent)
+}
+
+func tracer(ctx *core.Context) trace.Tracer {
+	return config.ByCtx(ctx).GetTracerProvider().Tracer(instrumentationName)
+}
+
+// startStageSpan starts a child span of the request's root span (if any)
+// for stage and stashes it in ctx so endStageSpan, registered via
+// UseFinally, can close it whether or not the stage succeeded.
+func startStageSpan(stage Stage) Handler {
+	return func(ctx *core.Context, _ *request.Request) {
+		root, ok := ctx.Get(ctxKeyRootSpan)
+		if !ok {
+			return
+		}
+		rootSpan, ok := root.(trace.Span)
+		if !ok {
+			return
+		}
+		_, span := tracer(ctx).Start(trace.ContextWithSpan(ctx, rootSpan), string(stage))
+		ctx.Set(ctxKeyStagePrefix+string(stage), span)
+	}
+}
+
+// endStageSpan ends the span startStageSpan created for stage, recording
+// req.Err onto it first if the stage failed.
+func endStageSpan(stage Stage) Handler {
+	return func(ctx *core.Context, req *request.Request) {
+		v, ok := ctx.Get(ctxKeyStagePrefix + string(stage))
+		if !ok {
+			return
+		}
+		span, ok := v.(trace.Span)
+		if !ok {
+			return
+		}
+		if req.Err != nil {
+			span.RecordError(req.Err)
+		}
+		span.End()
+	}
+}
+
+// injectTraceparent propagates the active span onto the outgoing request's
+// headers once StageBuild has produced req.HTTPRequest.
+func injectTraceparent(ctx *core.Context, req *request.Request) {
+	if req.HTTPRequest == nil {
+		return
+	}
+	root, ok := ctx.Get(ctxKeyRootSpan)
+	if !ok {
+		return
+	}
+	rootSpan, ok := root.(trace.Span)
+	if !ok {
+		return
+	}
+	spanCtx := trace.ContextWithSpan(ctx, rootSpan)
+	otel.GetTextMapPropagator().Inject(spanCtx, propagation.HeaderCarrier(req.HTTPRequest.Header))
+}
+
+// requestInstruments bundles the metrics Handle records for every call.
+type requestInstruments struct {
+	requestCount      metric.Int64Counter
+	retryCount        metric.Int64Counter
+	tokenRefreshCount metric.Int64Counter
+	latencyMs         metric.Float64Histogram
+	cacheHitCount     metric.Int64Counter
+	cacheMissCount    metric.Int64Counter
+	circuitOpenCount  metric.Int64Counter
+}
+
+func newRequestInstruments(conf *config.Config) *requestInstruments {
+	meter := conf.GetMeterProvider().Meter(instrumentationName)
+	instruments := &requestInstruments{}
+	instruments.requestCount, _ = meter.Int64Counter("lark.request.count")
+	instruments.retryCount, _ = meter.Int64Counter("lark.request.retry.count")
+	instruments.tokenRefreshCount, _ = meter.Int64Counter("lark.token.refresh.count")
+	instruments.latencyMs, _ = meter.Float64Histogram("lark.request.latency_ms")
+	instruments.cacheHitCount, _ = meter.Int64Counter("lark.cache.hit.count")
+	instruments.cacheMissCount, _ = meter.Int64Counter("lark.cache.miss.count")
+	instruments.circuitOpenCount, _ = meter.Int64Counter("lark.circuit.open.count")
+	return instruments
+}
+
+// routeOf returns the path component of req's full URL, falling back to the
+// full URL if it doesn't parse, so the request counter can be labeled by
+// route instead of by full URL (which would vary per path parameter).
+func routeOf(fullURL string) string {
+	u, err := url.Parse(fullURL)
+	if err != nil {
+		return fullURL
+	}
+	return u.Path
+}
+
+func requestSpanAttributes(conf *config.Config, req *request.Request, fullURL string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("lark.access_token_type", string(req.AccessTokenType)),
+		attribute.String("lark.app_id", conf.GetAppSettings().AppID),
+		attribute.String("http.method", req.HttpMethod),
+		attribute.String("http.url", fullURL),
+		attribute.String("net.peer.name", conf.GetDomain()),
+	}
+}
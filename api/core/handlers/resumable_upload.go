@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/larksuite/oapi-sdk-go/api/core/request"
+	"github.com/larksuite/oapi-sdk-go/core"
+)
+
+// ResumableUploader drives the session-start/chunk/finalize handshake for a
+// request.ResumableUpload. It sits parallel to reqBodyFromFormData: where
+// that builds one FormData request body, this issues many requests through
+// Handle so auth, retries, and middleware hooks keep working unchanged.
+type ResumableUploader interface {
+	Upload(ctx *core.Context, upload *request.ResumableUpload) error
+}
+
+// DefaultResumableUploader is the built-in ResumableUploader used by
+// UploadResumable when callers do not supply their own.
+var DefaultResumableUploader ResumableUploader = &resumableUploader{}
+
+// UploadResumable drives upload through DefaultResumableUploader.
+func UploadResumable(ctx *core.Context, upload *request.ResumableUpload) error {
+	return DefaultResumableUploader.Upload(ctx, upload)
+}
+
+type resumableUploader struct{}
+
+type resumableSession struct {
+	SessionID string `json:"session_id"`
+}
+
+type resumableChunkResult struct {
+	CommittedOffset int64 `json:"committed_offset"`
+}
+
+type chunkRange struct {
+	Start, End int64 // [Start, End)
+}
+
+func splitChunks(size, chunkSize int64) []chunkRange {
+	var chunks []chunkRange
+	for start := int64(0); start < size; start += chunkSize {
+		end := start + chunkSize
+		if end > size {
+			end = size
+		}
+		chunks = append(chunks, chunkRange{Start: start, End: end})
+	}
+	return chunks
+}
+
+// Upload splits upload into fixed-size chunks and uploads them with bounded
+// concurrency; a chunk that keeps failing cancels its siblings instead of
+// restarting the whole upload.
+func (u *resumableUploader) Upload(ctx *core.Context, upload *request.ResumableUpload) error {
+	session, err := u.start(ctx, upload)
+	if err != nil {
+		return err
+	}
+	chunks := splitChunks(upload.Size, upload.ChunkSize)
+	if err := u.uploadChunks(ctx, upload, session, chunks); err != nil {
+		return err
+	}
+	return u.finalize(ctx, upload, session)
+}
+
+// uploadChunks runs chunks with at most upload.Concurrency in flight. The
+// first chunk to exhaust its own retries cancels the remaining ones — both
+// by stopping new chunks from being dispatched and, via batchCtx, by
+// canceling whichever chunk requests are already in flight instead of
+// letting them run (and retry) to completion after the upload is doomed.
+func (u *resumableUploader) uploadChunks(ctx *core.Context, upload *request.ResumableUpload, session *resumableSession, chunks []chunkRange) error {
+	return u.runChunks(ctx, upload, chunks, func(chunkCtx *core.Context, c chunkRange) error {
+		return u.uploadChunk(chunkCtx, upload, session, c)
+	})
+}
+
+// runChunks is uploadChunks' concurrency and cancellation engine, with the
+// per-chunk request taken as dispatch rather than called directly, so tests
+// can substitute a fake in place of a real uploadChunk/Handle round trip.
+func (u *resumableUploader) runChunks(ctx *core.Context, upload *request.ResumableUpload, chunks []chunkRange, dispatch func(chunkCtx *core.Context, c chunkRange) error) error {
+	concurrency := upload.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+
+	batchCtx, cancelBatch := context.WithCancel(ctx)
+	defer cancelBatch()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		aborted  int32
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			atomic.StoreInt32(&aborted, 1)
+			cancelBatch()
+		})
+	}
+	var committed int64
+	var committedMu sync.Mutex
+
+	for _, c := range chunks {
+		if atomic.LoadInt32(&aborted) == 1 {
+			break
+		}
+		c := c
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if atomic.LoadInt32(&aborted) == 1 {
+				return
+			}
+			if err := dispatch(core.WarpContext(batchCtx), c); err != nil {
+				fail(err)
+				return
+			}
+			if upload.OnProgress != nil {
+				committedMu.Lock()
+				committed += c.End - c.Start
+				uploaded := committed
+				committedMu.Unlock()
+				upload.OnProgress(uploaded, upload.Size)
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func (u *resumableUploader) start(ctx *core.Context, upload *request.ResumableUpload) (*resumableSession, error) {
+	session := &resumableSession{}
+	req := request.NewRequestByAuth(upload.Path+"/upload_sessions", http.MethodPost,
+		map[string]interface{}{"file_size": upload.Size}, session)
+	Handle(ctx, req)
+	if req.Err != nil {
+		return nil, req.Err
+	}
+	return session, nil
+}
+
+func (u *resumableUploader) uploadChunk(ctx *core.Context, upload *request.ResumableUpload, session *resumableSession, c chunkRange) error {
+	section := io.NewSectionReader(upload.File, c.Start, c.End-c.Start)
+	result := &resumableChunkResult{}
+	req := request.NewRequestByAuth(fmt.Sprintf("%s/upload_sessions/%s", upload.Path, session.SessionID),
+		"PATCH", section, result)
+	req.ContentRange = fmt.Sprintf("bytes %d-%d/%d", c.Start, c.End-1, upload.Size)
+	Handle(ctx, req)
+	if req.Err != nil {
+		return req.Err
+	}
+	if result.CommittedOffset != c.End {
+		return fmt.Errorf("resumable upload: server committed offset %d, expected %d", result.CommittedOffset, c.End)
+	}
+	return nil
+}
+
+func (u *resumableUploader) finalize(ctx *core.Context, upload *request.ResumableUpload, session *resumableSession) error {
+	req := request.NewRequestByAuth(fmt.Sprintf("%s/upload_sessions/%s/finalize", upload.Path, session.SessionID),
+		http.MethodPost, nil, &struct{}{})
+	Handle(ctx, req)
+	return req.Err
+}
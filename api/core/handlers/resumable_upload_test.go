@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/larksuite/oapi-sdk-go/api/core/request"
+	"github.com/larksuite/oapi-sdk-go/core"
+)
+
+func TestSplitChunks(t *testing.T) {
+	got := splitChunks(12, 5)
+	want := []chunkRange{{Start: 0, End: 5}, {Start: 5, End: 10}, {Start: 10, End: 12}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d chunks, got %d: %+v", len(want), len(got), got)
+	}
+	for i, c := range got {
+		if c != want[i] {
+			t.Fatalf("chunk %d: expected %+v, got %+v", i, want[i], c)
+		}
+	}
+}
+
+func TestSplitChunksExactMultiple(t *testing.T) {
+	got := splitChunks(10, 5)
+	want := []chunkRange{{Start: 0, End: 5}, {Start: 5, End: 10}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d chunks, got %d: %+v", len(want), len(got), got)
+	}
+}
+
+func TestReqBodyFromSectionReaderStreamsRawBytes(t *testing.T) {
+	f, err := ioutil.TempFile("", "resumable-upload-test")
+	if err != nil {
+		t.Fatalf("TempFile: %v", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	data := []byte("this is chunk data, not a value to JSON-encode")
+	if _, err := f.Write(data); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	section := io.NewSectionReader(f, 0, int64(len(data)))
+	req := &request.Request{Input: section}
+	reqBodyFromSectionReader(nil, req)
+
+	if req.ContentType != "application/octet-stream" {
+		t.Fatalf("expected octet-stream content type, got %q", req.ContentType)
+	}
+	if req.RequestBody != nil {
+		t.Fatalf("expected RequestBody to stay nil so it isn't JSON-encoded, got %q", req.RequestBody)
+	}
+	got, err := ioutil.ReadAll(req.RequestBodyStream.(io.Reader))
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected body %q, got %q", data, got)
+	}
+}
+
+// TestUploadChunksCancelsInFlightSiblingsOnFailure drives runChunks with a
+// fake dispatch instead of a real uploadChunk/Handle round trip: one chunk
+// fails outright while a sibling is still in flight, and the sibling must
+// observe batchCtx being canceled rather than being left to run to
+// completion after the upload is already doomed.
+func TestUploadChunksCancelsInFlightSiblingsOnFailure(t *testing.T) {
+	u := &resumableUploader{}
+	upload := &request.ResumableUpload{Size: 30, ChunkSize: 10, Concurrency: 2}
+	chunks := splitChunks(upload.Size, upload.ChunkSize)
+
+	inFlight := make(chan struct{})
+	siblingCanceled := make(chan error, 1)
+	var calls sync.Map // chunk Start -> struct{}, records which chunks were dispatched at all
+
+	dispatch := func(chunkCtx *core.Context, c chunkRange) error {
+		calls.Store(c.Start, struct{}{})
+		switch c.Start {
+		case 0:
+			<-inFlight // fail only once the sibling is actually in flight
+			return errors.New("chunk 0: retries exhausted")
+		case 10:
+			close(inFlight)
+			<-chunkCtx.Done()
+			siblingCanceled <- chunkCtx.Err()
+			return chunkCtx.Err()
+		default:
+			return nil
+		}
+	}
+
+	err := u.runChunks(core.WarpContext(context.Background()), upload, chunks, dispatch)
+	if err == nil || err.Error() != "chunk 0: retries exhausted" {
+		t.Fatalf("expected runChunks to return the failing chunk's error, got %v", err)
+	}
+
+	select {
+	case sibErr := <-siblingCanceled:
+		if !errors.Is(sibErr, context.Canceled) {
+			t.Fatalf("expected the in-flight sibling to observe context.Canceled, got %v", sibErr)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the in-flight sibling chunk to be canceled, but it never observed cancellation")
+	}
+
+	<-inFlight // sanity: the sibling did actually start before the batch was aborted
+	if _, dispatched := calls.Load(int64(20)); dispatched {
+		t.Fatalf("expected the third chunk to never be dispatched once the batch was aborted")
+	}
+}
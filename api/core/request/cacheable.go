@@ -0,0 +1,13 @@
+package request
+
+import "time"
+
+// SetCacheable marks the request as eligible for handlers' response cache.
+// Responses are considered fresh for ttl before being revalidated with the
+// origin via If-None-Match/If-Modified-Since; a ttl of 0 means the entry
+// never expires and is served without revalidation. Only GET requests are
+// ever actually served from cache.
+func (r *Request) SetCacheable(ttl time.Duration) {
+	r.Cacheable = true
+	r.CacheTTL = ttl
+}
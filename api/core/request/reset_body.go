@@ -0,0 +1,39 @@
+package request
+
+import (
+	"io"
+	"os"
+)
+
+// ResetBody rewinds RequestBodyStream so a failed request can be replayed on
+// retry. A body that is not an io.Seeker (such as the *bytes.Buffer handlers
+// builds from RequestBody) is left as it is; a seekable body is seeked back
+// to the start. For a body
+// backed by a file (e.g. a multipart upload spooled to a temp file by
+// FormData) whose descriptor was already closed by the previous attempt, it
+// reopens the file by name, extending the same seek/reopen logic handlers
+// uses when first building the request.
+func (r *Request) ResetBody() error {
+	seeker, ok := r.RequestBodyStream.(io.Seeker)
+	if !ok {
+		return nil
+	}
+	_, err := seeker.Seek(0, io.SeekStart)
+	if err == nil {
+		return nil
+	}
+	pathErr, ok := err.(*os.PathError)
+	if !ok || pathErr.Err != os.ErrClosed {
+		return err
+	}
+	file, ok := seeker.(*os.File)
+	if !ok {
+		return err
+	}
+	reopened, openErr := os.Open(file.Name())
+	if openErr != nil {
+		return openErr
+	}
+	r.RequestBodyStream = reopened
+	return nil
+}
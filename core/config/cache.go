@@ -0,0 +1,29 @@
+package config
+
+import "github.com/larksuite/oapi-sdk-go/api/core/cache"
+
+// defaultResponseCacheShardCapacity bounds the in-memory LRU
+// GetResponseCache lazily installs when no ResponseCache has been
+// configured, matching cache.LRU's own default.
+const defaultResponseCacheShardCapacity = 256
+
+// GetResponseCache returns the configured cache.ResponseCache, lazily
+// building a default in-memory cache.LRU on first use — the same pattern
+// GetTransport and GetCircuitBreaker use — so a cacheable request works out
+// of the box without requiring SetResponseCache. Config is shared across
+// concurrent Handle calls, so the lazy build is guarded by
+// responseCacheOnce rather than a bare nil check.
+func (c *Config) GetResponseCache() cache.ResponseCache {
+	c.responseCacheOnce.Do(func() {
+		if c.responseCache == nil {
+			c.responseCache = cache.NewLRU(defaultResponseCacheShardCapacity)
+		}
+	})
+	return c.responseCache
+}
+
+// SetResponseCache installs the cache.ResponseCache handlers consult for
+// requests marked cacheable via request.SetCacheable.
+func (c *Config) SetResponseCache(rc cache.ResponseCache) {
+	c.responseCache = rc
+}
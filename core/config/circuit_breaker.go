@@ -0,0 +1,239 @@
+package config
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is a route's position in the classic circuit breaker cycle.
+type BreakerState int
+
+const (
+	// BreakerClosed passes every request through and tracks their outcomes.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects every request until OpenDuration has elapsed.
+	BreakerOpen
+	// BreakerHalfOpen lets up to HalfOpenProbeCount requests through to
+	// decide whether the route has recovered.
+	BreakerHalfOpen
+)
+
+// String implements fmt.Stringer for use in logs and monitoring callbacks.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker lets Handle fail fast on a route that's been erroring
+// repeatedly, instead of piling on more (likely doomed) requests while the
+// backend recovers. Allow is consulted before every attempt on a route,
+// retries included; RecordResult reports how each attempt turned out. success
+// must be false only for 5xx responses and network errors — 4xx client
+// errors should be reported as success so a burst of bad input doesn't trip
+// the breaker for every other caller of the same route.
+type CircuitBreaker interface {
+	Allow(route string) bool
+	RecordResult(route string, success bool)
+}
+
+// routeBreaker is one route's rolling window and state.
+type routeBreaker struct {
+	mu    sync.Mutex
+	state BreakerState
+
+	window []bool // ring buffer of the last len(window) outcomes, true == success
+	pos    int
+	filled int
+
+	openedAt            time.Time
+	halfOpenProbesInUse int
+	halfOpenSuccesses   int
+}
+
+// FailureRatioBreaker is the default CircuitBreaker. Per route, it keeps a
+// rolling window of the last WindowSize outcomes and opens the route once
+// the failure ratio over a full window reaches FailureThreshold. After
+// OpenDuration it moves to half-open and lets HalfOpenProbeCount requests
+// through: a single failure among them reopens the route immediately; once
+// all of them succeed the route closes and its window resets.
+type FailureRatioBreaker struct {
+	WindowSize         int
+	FailureThreshold   float64
+	OpenDuration       time.Duration
+	HalfOpenProbeCount int
+
+	// OnStateChange, if set, is called whenever a route transitions between
+	// states — wire it up to a metrics or alerting callback to monitor the
+	// breaker from outside the request path.
+	OnStateChange func(route string, state BreakerState)
+
+	mu     sync.Mutex
+	routes map[string]*routeBreaker
+}
+
+// DefaultCircuitBreaker returns the FailureRatioBreaker used when a Config
+// has none configured: open once 50% of the last 20 requests on a route
+// failed, stay open for 30s, then probe with a single half-open request.
+func DefaultCircuitBreaker() *FailureRatioBreaker {
+	return &FailureRatioBreaker{
+		WindowSize:         20,
+		FailureThreshold:   0.5,
+		OpenDuration:       30 * time.Second,
+		HalfOpenProbeCount: 1,
+	}
+}
+
+func (b *FailureRatioBreaker) windowSize() int {
+	if b.WindowSize <= 0 {
+		return 20
+	}
+	return b.WindowSize
+}
+
+func (b *FailureRatioBreaker) halfOpenProbeCount() int {
+	if b.HalfOpenProbeCount <= 0 {
+		return 1
+	}
+	return b.HalfOpenProbeCount
+}
+
+func (b *FailureRatioBreaker) routeFor(route string) *routeBreaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.routes == nil {
+		b.routes = make(map[string]*routeBreaker)
+	}
+	s, ok := b.routes[route]
+	if !ok {
+		s = &routeBreaker{}
+		b.routes[route] = s
+	}
+	return s
+}
+
+// setState transitions s to newState and fires OnStateChange, called with
+// s.mu already held.
+func (b *FailureRatioBreaker) setState(route string, s *routeBreaker, newState BreakerState) {
+	if s.state == newState {
+		return
+	}
+	s.state = newState
+	if b.OnStateChange != nil {
+		b.OnStateChange(route, newState)
+	}
+}
+
+// resetWindow clears s's rolling window, called with s.mu already held when
+// a route closes again and should judge its next failures fresh.
+func resetWindow(s *routeBreaker) {
+	s.window = nil
+	s.pos = 0
+	s.filled = 0
+}
+
+// Allow implements CircuitBreaker.
+func (b *FailureRatioBreaker) Allow(route string) bool {
+	s := b.routeFor(route)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == BreakerOpen && time.Since(s.openedAt) >= b.OpenDuration {
+		b.setState(route, s, BreakerHalfOpen)
+		s.halfOpenProbesInUse = 0
+		s.halfOpenSuccesses = 0
+	}
+
+	switch s.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if s.halfOpenProbesInUse >= b.halfOpenProbeCount() {
+			return false
+		}
+		s.halfOpenProbesInUse++
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordResult implements CircuitBreaker.
+func (b *FailureRatioBreaker) RecordResult(route string, success bool) {
+	s := b.routeFor(route)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == BreakerHalfOpen {
+		if !success {
+			resetWindow(s)
+			s.openedAt = time.Now()
+			b.setState(route, s, BreakerOpen)
+			return
+		}
+		s.halfOpenSuccesses++
+		if s.halfOpenSuccesses >= b.halfOpenProbeCount() {
+			resetWindow(s)
+			b.setState(route, s, BreakerClosed)
+		}
+		return
+	}
+
+	n := b.windowSize()
+	if s.window == nil {
+		s.window = make([]bool, n)
+	}
+	s.window[s.pos%n] = success
+	s.pos++
+	if s.filled < n {
+		s.filled++
+	}
+
+	if s.state == BreakerClosed && s.filled >= n && b.failureRatio(s) >= b.FailureThreshold {
+		s.openedAt = time.Now()
+		b.setState(route, s, BreakerOpen)
+	}
+}
+
+// failureRatio returns the fraction of failures among s's filled window
+// entries, called with s.mu already held.
+func (b *FailureRatioBreaker) failureRatio(s *routeBreaker) float64 {
+	if s.filled == 0 {
+		return 0
+	}
+	failures := 0
+	for i := 0; i < s.filled; i++ {
+		if !s.window[i] {
+			failures++
+		}
+	}
+	return float64(failures) / float64(s.filled)
+}
+
+// GetCircuitBreaker returns the configured CircuitBreaker, lazily building
+// the default FailureRatioBreaker on first use so its per-route state
+// persists across requests made with this Config. Config is shared across
+// concurrent Handle calls, so the lazy build is guarded by
+// circuitBreakerOnce rather than a bare nil check — otherwise two
+// goroutines racing on first use could each build their own breaker and
+// silently drop whichever one lost the race, along with every failure it
+// had already recorded.
+func (c *Config) GetCircuitBreaker() CircuitBreaker {
+	c.circuitBreakerOnce.Do(func() {
+		if c.circuitBreaker == nil {
+			c.circuitBreaker = DefaultCircuitBreaker()
+		}
+	})
+	return c.circuitBreaker
+}
+
+// SetCircuitBreaker overrides the CircuitBreaker used by Handle for this
+// Config.
+func (c *Config) SetCircuitBreaker(cb CircuitBreaker) {
+	c.circuitBreaker = cb
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestBreaker() *FailureRatioBreaker {
+	return &FailureRatioBreaker{
+		WindowSize:         4,
+		FailureThreshold:   0.5,
+		OpenDuration:       10 * time.Millisecond,
+		HalfOpenProbeCount: 2,
+	}
+}
+
+func TestFailureRatioBreakerStaysClosedBelowThreshold(t *testing.T) {
+	b := newTestBreaker()
+	const route = "/a"
+	for i := 0; i < 4; i++ {
+		if !b.Allow(route) {
+			t.Fatalf("expected closed breaker to allow request %d", i)
+		}
+		b.RecordResult(route, i != 0) // 1 failure out of 4 == 25%, below 50% threshold
+	}
+	if !b.Allow(route) {
+		t.Fatalf("expected breaker to remain closed below the failure threshold")
+	}
+}
+
+func TestFailureRatioBreakerOpensAtThreshold(t *testing.T) {
+	b := newTestBreaker()
+	const route = "/a"
+	results := []bool{true, false, false, true} // 2/4 == 50%, at threshold
+	for _, ok := range results {
+		b.Allow(route)
+		b.RecordResult(route, ok)
+	}
+	if b.Allow(route) {
+		t.Fatalf("expected breaker to open once the failure ratio reached FailureThreshold")
+	}
+}
+
+func Test4xxErrorsDoNotCountAsFailures(t *testing.T) {
+	b := newTestBreaker()
+	const route = "/a"
+	// A caller classifying 4xx responses as success (per isCircuitFailure in
+	// the handlers package) should never trip the breaker, no matter how
+	// many bad requests land on the route.
+	for i := 0; i < 20; i++ {
+		b.Allow(route)
+		b.RecordResult(route, true)
+	}
+	if !b.Allow(route) {
+		t.Fatalf("expected breaker fed only non-failures to stay closed")
+	}
+}
+
+func TestFailureRatioBreakerHalfOpenRecoversAfterOpenDuration(t *testing.T) {
+	b := newTestBreaker()
+	const route = "/a"
+	for _, ok := range []bool{false, false, false, false} {
+		b.Allow(route)
+		b.RecordResult(route, ok)
+	}
+	if b.Allow(route) {
+		t.Fatalf("expected breaker to be open immediately after tripping")
+	}
+
+	time.Sleep(b.OpenDuration * 2)
+
+	for i := 0; i < b.HalfOpenProbeCount; i++ {
+		if !b.Allow(route) {
+			t.Fatalf("expected half-open probe %d to be allowed", i)
+		}
+	}
+	if b.Allow(route) {
+		t.Fatalf("expected only HalfOpenProbeCount probes to be allowed while half-open")
+	}
+	for i := 0; i < b.HalfOpenProbeCount; i++ {
+		b.RecordResult(route, true)
+	}
+	if !b.Allow(route) {
+		t.Fatalf("expected breaker to close again once every half-open probe succeeded")
+	}
+}
+
+func TestFailureRatioBreakerHalfOpenProbeFailureReopens(t *testing.T) {
+	b := newTestBreaker()
+	const route = "/a"
+	for _, ok := range []bool{false, false, false, false} {
+		b.Allow(route)
+		b.RecordResult(route, ok)
+	}
+	time.Sleep(b.OpenDuration * 2)
+
+	if !b.Allow(route) {
+		t.Fatalf("expected first half-open probe to be allowed")
+	}
+	b.RecordResult(route, false)
+
+	if b.Allow(route) {
+		t.Fatalf("expected a failed half-open probe to reopen the breaker immediately")
+	}
+}
+
+func TestFailureRatioBreakerOnStateChangeCallback(t *testing.T) {
+	b := newTestBreaker()
+	var transitions []BreakerState
+	b.OnStateChange = func(route string, state BreakerState) {
+		transitions = append(transitions, state)
+	}
+	const route = "/a"
+	for _, ok := range []bool{false, false, false, false} {
+		b.Allow(route)
+		b.RecordResult(route, ok)
+	}
+	if len(transitions) != 1 || transitions[0] != BreakerOpen {
+		t.Fatalf("expected a single transition to BreakerOpen, got %v", transitions)
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetTracerProvider returns the configured trace.TracerProvider. When none
+// has been set via SetTracerProvider it falls back to otel's global
+// provider, which is a no-op until something calls otel.SetTracerProvider.
+func (c *Config) GetTracerProvider() trace.TracerProvider {
+	if c.tracerProvider != nil {
+		return c.tracerProvider
+	}
+	return otel.GetTracerProvider()
+}
+
+// SetTracerProvider installs the trace.TracerProvider Handle uses to emit
+// spans for this Config.
+func (c *Config) SetTracerProvider(tp trace.TracerProvider) {
+	c.tracerProvider = tp
+}
+
+// GetMeterProvider returns the configured metric.MeterProvider, falling
+// back to otel's global (no-op by default) provider when none is set.
+func (c *Config) GetMeterProvider() metric.MeterProvider {
+	if c.meterProvider != nil {
+		return c.meterProvider
+	}
+	return otel.GetMeterProvider()
+}
+
+// SetMeterProvider installs the metric.MeterProvider Handle uses to record
+// metrics for this Config.
+func (c *Config) SetMeterProvider(mp metric.MeterProvider) {
+	c.meterProvider = mp
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestGetTracerProviderFallsBackToGlobal(t *testing.T) {
+	c := &Config{}
+	if got, want := c.GetTracerProvider(), otel.GetTracerProvider(); got != want {
+		t.Fatalf("expected an unconfigured Config to fall back to otel's global TracerProvider, got %v want %v", got, want)
+	}
+}
+
+type fakeTracerProvider struct{}
+
+func (fakeTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer { return nil }
+
+func TestSetTracerProviderOverridesGlobal(t *testing.T) {
+	c := &Config{}
+	tp := fakeTracerProvider{}
+	c.SetTracerProvider(tp)
+	if got := c.GetTracerProvider(); got != trace.TracerProvider(tp) {
+		t.Fatalf("expected GetTracerProvider to return the provider set via SetTracerProvider")
+	}
+}
+
+func TestGetMeterProviderFallsBackToGlobal(t *testing.T) {
+	c := &Config{}
+	if got, want := c.GetMeterProvider(), otel.GetMeterProvider(); got != want {
+		t.Fatalf("expected an unconfigured Config to fall back to otel's global MeterProvider, got %v want %v", got, want)
+	}
+}
+
+type fakeMeterProvider struct{}
+
+func (fakeMeterProvider) Meter(name string, opts ...metric.MeterOption) metric.Meter { return nil }
+
+func TestSetMeterProviderOverridesGlobal(t *testing.T) {
+	c := &Config{}
+	mp := fakeMeterProvider{}
+	c.SetMeterProvider(mp)
+	if got := c.GetMeterProvider(); got != metric.MeterProvider(mp) {
+		t.Fatalf("expected GetMeterProvider to return the provider set via SetMeterProvider")
+	}
+}
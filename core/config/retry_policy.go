@@ -0,0 +1,131 @@
+package config
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/larksuite/oapi-sdk-go/api/core/request"
+)
+
+// RetryPolicy decides, after a failed attempt, whether Handle should retry
+// req and how long to wait before doing so. resp is nil when err came from
+// the transport rather than the server. start is when Handle began its
+// first attempt at req, so implementations can measure elapsed time without
+// keeping any state of their own — a RetryPolicy is shared across every
+// concurrent call made with the same Config, so it must not mutate itself
+// to track per-request state.
+type RetryPolicy interface {
+	NextBackoff(attempt int, start time.Time, req *request.Request, resp *http.Response, err error) (delay time.Duration, retry bool)
+}
+
+// ExponentialBackoff is the default RetryPolicy. The delay grows
+// exponentially with the attempt number, bounded by MaxInterval, jittered by
+// RandomizationFactor, and retries stop once MaxElapsedTime has passed since
+// the first attempt. A Retry-After header on resp takes precedence over the
+// computed delay.
+type ExponentialBackoff struct {
+	InitialInterval      time.Duration
+	Multiplier           float64
+	MaxInterval          time.Duration
+	MaxElapsedTime       time.Duration
+	RandomizationFactor  float64
+	RetryableStatusCodes map[int]bool
+}
+
+// DefaultRetryPolicy returns the ExponentialBackoff used when a Config has
+// none configured: 500ms initial interval doubling up to 30s, retrying
+// 429/5xx responses and network errors for up to 2 minutes.
+func DefaultRetryPolicy() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		InitialInterval:     500 * time.Millisecond,
+		Multiplier:          2,
+		MaxInterval:         30 * time.Second,
+		MaxElapsedTime:      2 * time.Minute,
+		RandomizationFactor: 0.5,
+		RetryableStatusCodes: map[int]bool{
+			http.StatusTooManyRequests:     true,
+			http.StatusInternalServerError: true,
+			http.StatusBadGateway:          true,
+			http.StatusServiceUnavailable:  true,
+			http.StatusGatewayTimeout:      true,
+		},
+	}
+}
+
+// NextBackoff implements RetryPolicy.
+func (b *ExponentialBackoff) NextBackoff(attempt int, start time.Time, _ *request.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if b.MaxElapsedTime > 0 && time.Since(start) > b.MaxElapsedTime {
+		return 0, false
+	}
+	if !b.isRetryable(resp, err) {
+		return 0, false
+	}
+	if delay, ok := retryAfter(resp); ok {
+		return delay, true
+	}
+	return b.backoff(attempt), true
+}
+
+func (b *ExponentialBackoff) isRetryable(resp *http.Response, err error) bool {
+	if resp == nil {
+		return err != nil
+	}
+	// resp != nil means the server answered, even if unmarshalJSON turned a
+	// business error code into a non-nil err — that's a client-facing
+	// response, not a transport failure, so judge it by status code only.
+	if b.RetryableStatusCodes[resp.StatusCode] {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+func (b *ExponentialBackoff) backoff(attempt int) time.Duration {
+	delay := float64(b.InitialInterval) * math.Pow(b.Multiplier, float64(attempt))
+	if b.MaxInterval > 0 && delay > float64(b.MaxInterval) {
+		delay = float64(b.MaxInterval)
+	}
+	if b.RandomizationFactor > 0 {
+		delta := b.RandomizationFactor * delay
+		delay += delta * (2*rand.Float64() - 1)
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return time.Duration(delay)
+}
+
+// retryAfter reads the Retry-After header, supporting both the
+// delay-seconds and HTTP-date forms.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+// GetRetryPolicy returns the configured RetryPolicy, falling back to
+// DefaultRetryPolicy when none has been set via SetRetryPolicy.
+func (c *Config) GetRetryPolicy() RetryPolicy {
+	if c.retryPolicy == nil {
+		return DefaultRetryPolicy()
+	}
+	return c.retryPolicy
+}
+
+// SetRetryPolicy overrides the RetryPolicy used by Handle for this Config.
+func (c *Config) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoffNextBackoffGrowsAndCaps(t *testing.T) {
+	b := DefaultRetryPolicy()
+	b.RandomizationFactor = 0
+
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	start := time.Now()
+
+	delay0, retry := b.NextBackoff(0, start, nil, resp, nil)
+	if !retry || delay0 != b.InitialInterval {
+		t.Fatalf("attempt 0: expected retry with delay %v, got %v, retry=%v", b.InitialInterval, delay0, retry)
+	}
+	delay1, retry := b.NextBackoff(1, start, nil, resp, nil)
+	if !retry || delay1 != b.InitialInterval*2 {
+		t.Fatalf("attempt 1: expected delay %v, got %v, retry=%v", b.InitialInterval*2, delay1, retry)
+	}
+	delayMax, retry := b.NextBackoff(20, start, nil, resp, nil)
+	if !retry || delayMax != b.MaxInterval {
+		t.Fatalf("attempt 20: expected delay capped at %v, got %v, retry=%v", b.MaxInterval, delayMax, retry)
+	}
+}
+
+func TestExponentialBackoffStopsAfterMaxElapsedTime(t *testing.T) {
+	b := DefaultRetryPolicy()
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	staleStart := time.Now().Add(-(b.MaxElapsedTime + time.Second))
+
+	if _, retry := b.NextBackoff(0, staleStart, nil, resp, nil); retry {
+		t.Fatalf("expected no retry once MaxElapsedTime has passed since start")
+	}
+}
+
+// TestExponentialBackoffIsStatelessAcrossCalls guards against the policy
+// tracking its own "first attempt" time on itself: a single shared
+// *ExponentialBackoff (as installed via Config.SetRetryPolicy) must judge
+// each call strictly by the start it's given, not by when NextBackoff was
+// first invoked on the instance.
+func TestExponentialBackoffIsStatelessAcrossCalls(t *testing.T) {
+	b := DefaultRetryPolicy()
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+
+	staleStart := time.Now().Add(-(b.MaxElapsedTime + time.Second))
+	if _, retry := b.NextBackoff(0, staleStart, nil, resp, nil); retry {
+		t.Fatalf("expected no retry for a stale start")
+	}
+
+	freshStart := time.Now()
+	if _, retry := b.NextBackoff(0, freshStart, nil, resp, nil); !retry {
+		t.Fatalf("expected a later call with a fresh start to still retry, not be poisoned by the earlier stale one")
+	}
+}
+
+func TestExponentialBackoffNotRetryableFor4xx(t *testing.T) {
+	b := DefaultRetryPolicy()
+	resp := &http.Response{StatusCode: http.StatusBadRequest, Header: http.Header{}}
+	if _, retry := b.NextBackoff(0, time.Now(), nil, resp, nil); retry {
+		t.Fatalf("expected 400 to not be retried")
+	}
+}
+
+// TestExponentialBackoffNotRetryableForBusinessErrorOn200 guards against
+// treating a successfully-received response that carries a business error
+// code (unmarshalJSON sets req.Err to a *response.Error even though resp is
+// a plain 200) as a transport failure. Only resp == nil means err came from
+// the transport; a non-nil resp must be judged by status code alone.
+func TestExponentialBackoffNotRetryableForBusinessErrorOn200(t *testing.T) {
+	b := DefaultRetryPolicy()
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	businessErr := errors.New("business error: invalid param")
+	if _, retry := b.NextBackoff(0, time.Now(), nil, resp, businessErr); retry {
+		t.Fatalf("expected a business error on a 200 response to not be retried")
+	}
+}
+
+func TestRetryAfterHeaderTakesPrecedence(t *testing.T) {
+	b := DefaultRetryPolicy()
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"2"}}}
+	delay, retry := b.NextBackoff(5, time.Now(), nil, resp, nil)
+	if !retry || delay != 2*time.Second {
+		t.Fatalf("expected Retry-After to win with a 2s delay, got %v, retry=%v", delay, retry)
+	}
+}
@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+// GetRequestTimeout returns the per-request timeout Handle applies via
+// core.Context.SetTimeout when the caller's context has no deadline of its
+// own. Zero means no timeout is applied.
+func (c *Config) GetRequestTimeout() time.Duration {
+	return c.requestTimeout
+}
+
+// SetRequestTimeout configures the per-request timeout returned by
+// GetRequestTimeout.
+func (c *Config) SetRequestTimeout(d time.Duration) {
+	c.requestTimeout = d
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Transport sends a built *http.Request and returns the raw *http.Response,
+// the same shape as http.Client.Do. Implement it to inject httptrace, swap
+// in a custom *http.Client, or fake the network in tests.
+type Transport interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TransportConfig configures the Transport NewDefaultTransport builds.
+type TransportConfig struct {
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	MaxIdleConnsPerHost   int
+	IdleConnTimeout       time.Duration
+	RootCAs               *x509.CertPool
+	ClientCert            *tls.Certificate
+	DisableHTTP2          bool
+}
+
+// DefaultTransportConfig mirrors net/http's own defaults, made explicit so
+// callers can override individual knobs without rebuilding the rest.
+func DefaultTransportConfig() *TransportConfig {
+	return &TransportConfig{
+		DialTimeout:         30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConnsPerHost: http.DefaultMaxIdleConnsPerHost,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// NewDefaultTransport builds the *http.Client-backed Transport used when a
+// Config has no Transport of its own.
+func NewDefaultTransport(c *TransportConfig) Transport {
+	if c == nil {
+		c = DefaultTransportConfig()
+	}
+	tlsConfig := &tls.Config{RootCAs: c.RootCAs}
+	if c.ClientCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*c.ClientCert}
+	}
+	if c.DisableHTTP2 {
+		// The zero value negotiates h2; pinning NextProtos to http/1.1 is
+		// the standard way to opt a transport out of it.
+		tlsConfig.NextProtos = []string{"http/1.1"}
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout: c.DialTimeout,
+			}).DialContext,
+			TLSHandshakeTimeout:   c.TLSHandshakeTimeout,
+			ResponseHeaderTimeout: c.ResponseHeaderTimeout,
+			MaxIdleConnsPerHost:   c.MaxIdleConnsPerHost,
+			IdleConnTimeout:       c.IdleConnTimeout,
+			TLSClientConfig:       tlsConfig,
+			ForceAttemptHTTP2:     !c.DisableHTTP2,
+		},
+	}
+}
+
+// GetTransport returns the configured Transport, lazily building the
+// default one from the configured TransportConfig on first use. Config is
+// shared across concurrent Handle calls, so the lazy build is guarded by
+// transportOnce rather than a bare nil check.
+func (c *Config) GetTransport() Transport {
+	c.transportOnce.Do(func() {
+		if c.transport == nil {
+			c.transport = NewDefaultTransport(c.transportConfig)
+		}
+	})
+	return c.transport
+}
+
+// SetTransport overrides the Transport used for every request made with
+// this Config, e.g. to inject httptrace or a test double.
+func (c *Config) SetTransport(t Transport) {
+	c.transport = t
+}
+
+// SetTransportConfig sets the knobs NewDefaultTransport builds its Transport
+// from. It has no effect once SetTransport has installed a custom Transport.
+func (c *Config) SetTransportConfig(tc *TransportConfig) {
+	c.transportConfig = tc
+}
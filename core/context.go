@@ -52,14 +52,41 @@ func (c *Context) GetHTTPStatusCode() int {
 }
 
 func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.c.Deadline()
 }
 
+// SetTimeout tightens the wrapped context.Context's deadline to d from now,
+// if that's sooner than whatever deadline (if any) is already in effect.
+// Done() and Deadline() pick it up immediately since they read through to
+// the same wrapped context. It returns a cancel func the caller should
+// defer: it releases the underlying timer as soon as the request finishes
+// and puts the previously wrapped context back, so the timeout covers only
+// that request rather than every later use of c.
+func (c *Context) SetTimeout(d time.Duration) (cancel func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	parent := c.c
+	ctx, cancelFn := context.WithTimeout(parent, d)
+	c.c = ctx
+	return func() {
+		cancelFn()
+		c.mu.Lock()
+		c.c = parent
+		c.mu.Unlock()
+	}
+}
+
 func (c *Context) Done() <-chan struct{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.c.Done()
 }
 
 func (c *Context) Err() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.c.Err()
 }
 
@@ -68,5 +95,7 @@ func (c *Context) Value(key interface{}) interface{} {
 		val, _ := c.Get(keyAsString)
 		return val
 	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.c.Value(key)
 }